@@ -98,17 +98,17 @@ type Player struct {
 	HighScore int
 }
 
-// NewPlayer creates a new player. If color is below 1, a random color is chosen
-func NewPlayer(s *Session, worldWidth, worldHeight int,
+// NewPlayer creates a new player, drawing its starting position (and color,
+// if color is below 0) from rng. rng is per-game so that, given the same
+// seed and the same sequence of joins, a game can be replayed deterministically.
+func NewPlayer(s *Session, rng *rand.Rand, worldWidth, worldHeight int,
 	color color.Attribute) *Player {
 
-	rand.Seed(time.Now().UnixNano())
-
-	startX := rand.Float64() * float64(worldWidth)
-	startY := rand.Float64() * float64(worldHeight)
+	startX := rng.Float64() * float64(worldWidth)
+	startY := rng.Float64() * float64(worldHeight)
 
 	if color < 0 {
-		color = playerColors[rand.Intn(len(playerColors))]
+		color = playerColors[rng.Intn(len(playerColors))]
 	}
 
 	return &Player{
@@ -122,6 +122,21 @@ func NewPlayer(s *Session, worldWidth, worldHeight int,
 	}
 }
 
+// NewPlayerAt creates a player at an explicit starting position rather than a
+// randomly chosen one. It's used to reconstruct a recorded game during
+// replay, where the position was already determined by the original rng.
+func NewPlayerAt(s *Session, pos Position, color color.Attribute) *Player {
+	return &Player{
+		s:          s,
+		CreatedAt:  time.Now(),
+		Marker:     playerDownRune,
+		LastAction: time.Now(),
+		Direction:  PlayerDown,
+		Color:      color,
+		Pos:        &Position{pos.X, pos.Y},
+	}
+}
+
 func (p *Player) addTrailSegment(pos Position, marker rune) {
 	segment := PlayerTrailSegment{marker, pos}
 	p.Trail = append([]PlayerTrailSegment{segment}, p.Trail...)
@@ -257,23 +272,105 @@ func (slice ByColor) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
+// GameEventType identifies the kind of notable thing that just happened in a
+// Game, for consumers of Game.Events.
+type GameEventType int
+
+const (
+	EventPlayerJoined GameEventType = iota
+	EventPlayerLeft
+	EventPlayerCrashed
+	EventPlayerTimedOut
+	EventHighScore
+)
+
+// GameEvent is a single notification emitted on Game.Events. Session and
+// Player are the ones the event is about; Player is nil for events that
+// aren't about a particular player (there are none yet, but future
+// integrations may add them).
+type GameEvent struct {
+	Type    GameEventType
+	Game    *Game
+	Session *Session
+	Player  *Player
+}
+
+// eventsBuffer is how many unconsumed events Game.Events will hold before
+// emit starts dropping them rather than blocking the game loop.
+const eventsBuffer = 16
+
 type Game struct {
 	Name      string
 	Redraw    chan struct{}
 	HighScore int
 
+	// Named marks a game created for a specific lobby name rather than
+	// auto-placement, so random matchmaking can leave it alone: a named
+	// lobby is how a group of friends finds each other, and should never be
+	// handed a stranger just because it has an open spot.
+	Named bool
+
+	// Events is a fan-out-free pub/sub of notable things happening in this
+	// game (joins, leaves, crashes, high scores). The current subscriber is
+	// main.go's stats logger; spectators and other integrations can read
+	// from it too.
+	Events chan GameEvent
+
 	width    int
 	height   int
 	Sessions map[*Session]struct{}
+
+	// Observers are sessions watching the game without playing. They
+	// receive the same rendered frames as players but have no Player and
+	// can't move.
+	Observers map[*Session]struct{}
+
+	// Seed and rng drive every random draw a player makes (starting
+	// position, random color). Keeping them per-game, instead of reseeding
+	// the global rand on every NewPlayer call, is what makes a game
+	// reproducible from Seed and a recorded input log.
+	Seed int64
+	rng  *rand.Rand
+
+	// Tick counts Update calls since the game started. The recorder tags
+	// joins and inputs with it so replay can feed them back at the right
+	// moment.
+	Tick uint32
+
+	// Recorder is non-nil when RECORD_DIR is set, and captures everything
+	// needed to replay this game later.
+	Recorder *Recorder
 }
 
 func NewGame(worldWidth, worldHeight int) *Game {
+	return NewGameWithSeed(worldWidth, worldHeight, time.Now().UnixNano())
+}
+
+// NewGameWithSeed creates a game whose players' random draws come from seed,
+// making it reproducible. Used directly by replay to recreate a recorded
+// game.
+func NewGameWithSeed(worldWidth, worldHeight int, seed int64) *Game {
 	return &Game{
-		Name:     petname.Generate(1, ""),
-		Redraw:   make(chan struct{}),
-		Sessions: make(map[*Session]struct{}),
-		width:    worldWidth,
-		height:   worldHeight,
+		Name:      petname.Generate(1, ""),
+		Redraw:    make(chan struct{}),
+		Events:    make(chan GameEvent, eventsBuffer),
+		Sessions:  make(map[*Session]struct{}),
+		Observers: make(map[*Session]struct{}),
+		width:     worldWidth,
+		height:    worldHeight,
+		Seed:      seed,
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// emit publishes e, dropping it instead of blocking the game loop if nobody
+// is keeping up with Events.
+func (g *Game) emit(e GameEvent) {
+	e.Game = g
+
+	select {
+	case g.Events <- e:
+	default:
 	}
 }
 
@@ -309,8 +406,15 @@ func (g *Game) worldString(s *Session) string {
 		strWorld[x] = make([]string, worldHeight+2)
 	}
 
+	// Observers have no Player of their own, so they view the world in a
+	// neutral color.
+	borderColor := color.FgHiWhite
+	if !s.IsObserver {
+		borderColor = playerBorderColors[s.Player.Color]
+	}
+
 	// Load the walls into the rune slice
-	borderColorizer := color.New(playerBorderColors[s.Player.Color]).SprintFunc()
+	borderColorizer := color.New(borderColor).SprintFunc()
 	for x := 0; x < worldWidth+2; x++ {
 		strWorld[x][0] = borderColorizer(string(horizontalWall))
 		strWorld[x][worldHeight+1] = borderColorizer(string(horizontalWall))
@@ -326,38 +430,56 @@ func (g *Game) worldString(s *Session) string {
 	strWorld[worldWidth+1][worldHeight+1] = borderColorizer(string(bottomRight))
 	strWorld[0][worldHeight+1] = borderColorizer(string(bottomLeft))
 
-	// Draw the player's score
-	scoreStr := fmt.Sprintf(
-		" Score: %d : Your High Score: %d : Game High Score: %d ",
-		s.Player.Score(),
-		s.Player.HighScore,
-		g.HighScore,
-	)
-	for i, r := range scoreStr {
-		strWorld[3+i][0] = borderColorizer(string(r))
-	}
-
-	// Draw the player's color
-	colorStr := fmt.Sprintf(" %s ", playerColorNames[s.Player.Color])
-	colorStrColorizer := color.New(s.Player.Color).SprintFunc()
-	for i, r := range colorStr {
-		charsRemaining := len(colorStr) - i
-		strWorld[len(strWorld)-3-charsRemaining][0] = colorStrColorizer(string(r))
-	}
-
-	// Draw everyone's scores
-	if len(g.players()) > 1 {
-		// Sort the players by color name
-		players := []*Player{}
-
-		for player := range g.players() {
-			if player == s.Player {
-				continue
-			}
+	// set writes into strWorld, silently dropping anything that would fall
+	// outside it. The banners and score lists below are sized for the
+	// minimum playable arena, but a narrow enough one (or enough players'
+	// worth of scores) can still run past the right or bottom wall.
+	set := func(x, y int, val string) {
+		if x < 0 || x >= len(strWorld) || y < 0 || y >= len(strWorld[0]) {
+			return
+		}
+		strWorld[x][y] = val
+	}
+
+	if s.IsObserver {
+		// Draw a spectator banner instead of a personal score.
+		scoreStr := fmt.Sprintf(" Spectating : Game High Score: %d ", g.HighScore)
+		for i, r := range scoreStr {
+			set(3+i, 0, borderColorizer(string(r)))
+		}
+	} else {
+		// Draw the player's score
+		scoreStr := fmt.Sprintf(
+			" Score: %d : Your High Score: %d : Game High Score: %d ",
+			s.Player.Score(),
+			s.Player.HighScore,
+			g.HighScore,
+		)
+		for i, r := range scoreStr {
+			set(3+i, 0, borderColorizer(string(r)))
+		}
 
-			players = append(players, player)
+		// Draw the player's color
+		colorStr := fmt.Sprintf(" %s ", playerColorNames[s.Player.Color])
+		colorStrColorizer := color.New(s.Player.Color).SprintFunc()
+		for i, r := range colorStr {
+			charsRemaining := len(colorStr) - i
+			set(len(strWorld)-3-charsRemaining, 0, colorStrColorizer(string(r)))
 		}
+	}
 
+	// Draw everyone's scores. Observers see every player; players see
+	// everyone but themselves.
+	players := []*Player{}
+	for player := range g.players() {
+		if !s.IsObserver && player == s.Player {
+			continue
+		}
+
+		players = append(players, player)
+	}
+
+	if len(players) > 0 {
 		sort.Sort(ByColor(players))
 		startX := 3
 
@@ -369,18 +491,18 @@ func (g *Game) worldString(s *Session) string {
 				player.Score(),
 			)
 			for _, r := range scoreStr {
-				strWorld[startX][len(strWorld[0])-1] = colorizer(string(r))
+				set(startX, len(strWorld[0])-1, colorizer(string(r)))
 				startX++
 			}
 		}
 
 		// Add final spacing next to wall
-		strWorld[startX][len(strWorld[0])-1] = " "
+		set(startX, len(strWorld[0])-1, " ")
 	} else {
 		warning :=
 			" Warning: Other Players Must be in This Game for You to Score! "
 		for i, r := range warning {
-			strWorld[3+i][len(strWorld[0])-1] = borderColorizer(string(r))
+			set(3+i, len(strWorld[0])-1, borderColorizer(string(r)))
 		}
 	}
 
@@ -436,6 +558,45 @@ func (g *Game) WorldHeight() int {
 	return g.height
 }
 
+// Resize changes the playable area to width x height, clipping any player
+// positions and trail segments that would otherwise fall outside the new
+// bounds. worldString picks up the new size on its next call; this just
+// keeps shrinking the arena from instantly killing everyone in it.
+//
+// A game is only ever resized by one client's terminal, so it's ignored once
+// a second session joins: otherwise whoever last resized their window would
+// silently reshape (and clip) the arena out from under everybody else
+// sharing it.
+func (g *Game) Resize(width, height int) {
+	if width < minPlayableWidth || height < minPlayableHeight {
+		return
+	}
+
+	if g.SessionCount() > 1 {
+		return
+	}
+
+	for player := range g.players() {
+		if player.Pos.X >= float64(width) {
+			player.Pos.X = float64(width - 1)
+		}
+		if player.Pos.Y >= float64(height) {
+			player.Pos.Y = float64(height - 1)
+		}
+
+		trail := player.Trail[:0]
+		for _, seg := range player.Trail {
+			if seg.Pos.X < float64(width) && seg.Pos.Y < float64(height) {
+				trail = append(trail, seg)
+			}
+		}
+		player.Trail = trail
+	}
+
+	g.width = width
+	g.height = height
+}
+
 func (g *Game) AvailableColors() []color.Attribute {
 	usedColors := map[color.Attribute]bool{}
 	for _, color := range playerColors {
@@ -482,6 +643,9 @@ func (g *Game) Run() {
 			for s := range g.Sessions {
 				go g.Render(s)
 			}
+			for s := range g.Observers {
+				go g.Render(s)
+			}
 		}
 	}()
 }
@@ -489,6 +653,8 @@ func (g *Game) Run() {
 // Update is the main game logic loop. Delta is the time since the last update
 // in milliseconds.
 func (g *Game) Update(delta float64) {
+	g.Tick++
+
 	// We'll use this to make a set of all of the coordinates that are occupied by
 	// trails
 	trailCoordMap := make(map[string]bool)
@@ -505,18 +671,21 @@ func (g *Game) Update(delta float64) {
 		// Update global high score, if applicable
 		if player.Score() > g.HighScore {
 			g.HighScore = player.Score()
+			g.emit(GameEvent{Type: EventHighScore, Session: session, Player: player})
 		}
 
 		// Restart the player if they're out of bounds
 		pos := player.Pos
 		if pos.RoundX() < 0 || pos.RoundX() >= g.WorldWidth() ||
 			pos.RoundY() < 0 || pos.RoundY() >= g.WorldHeight() {
-			session.StartOver(g.WorldWidth(), g.WorldHeight())
+			g.emit(GameEvent{Type: EventPlayerCrashed, Session: session, Player: player})
+			session.StartOver()
 		}
 
 		// Kick the player if they've timed out
 		if time.Now().Sub(player.LastAction) > playerTimeout {
 			fmt.Fprint(session, "\r\n\r\nYou were terminated due to inactivity\r\n")
+			g.emit(GameEvent{Type: EventPlayerTimedOut, Session: session, Player: player})
 			g.RemoveSession(session)
 			return
 		}
@@ -531,7 +700,8 @@ func (g *Game) Update(delta float64) {
 	for player, session := range g.players() {
 		playerPos := fmt.Sprintf("%d,%d", player.Pos.RoundX(), player.Pos.RoundY())
 		if collided := trailCoordMap[playerPos]; collided {
-			session.StartOver(g.WorldWidth(), g.WorldHeight())
+			g.emit(GameEvent{Type: EventPlayerCrashed, Session: session, Player: player})
+			session.StartOver()
 		}
 	}
 }
@@ -543,6 +713,10 @@ func (g *Game) Render(s *Session) {
 	b.WriteString("\033[H\033[2J")
 	b.WriteString(worldStr)
 
+	if g.Recorder != nil {
+		g.Recorder.RecordOutput(s, b.String())
+	}
+
 	// Send over the rendered world
 	io.Copy(s, &b)
 }
@@ -552,6 +726,11 @@ func (g *Game) AddSession(s *Session) {
 	fmt.Fprint(s, "\033[?25l")
 
 	g.Sessions[s] = struct{}{}
+	g.emit(GameEvent{Type: EventPlayerJoined, Session: s, Player: s.Player})
+
+	if g.Recorder != nil {
+		g.Recorder.RecordJoin(g.Tick, s.Player)
+	}
 }
 
 func (g *Game) RemoveSession(s *Session) {
@@ -562,6 +741,28 @@ func (g *Game) RemoveSession(s *Session) {
 		fmt.Fprint(s, "\033[?25h")
 
 		delete(g.Sessions, s)
+		g.emit(GameEvent{Type: EventPlayerLeft, Session: s, Player: s.Player})
+		s.c.Close()
+	}
+}
+
+// AddObserver attaches s to the game as a read-only spectator: it receives
+// rendered frames like a player but has no Player and can't move.
+func (g *Game) AddObserver(s *Session) {
+	// Hide the cursor
+	fmt.Fprint(s, "\033[?25l")
+
+	g.Observers[s] = struct{}{}
+}
+
+func (g *Game) RemoveObserver(s *Session) {
+	if _, ok := g.Observers[s]; ok {
+		fmt.Fprint(s, "\r\n\r\n~ End of Line ~ \r\n\r\n")
+
+		// Unhide the cursor
+		fmt.Fprint(s, "\033[?25h")
+
+		delete(g.Observers, s)
 		s.c.Close()
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/fatih/color"
 )
@@ -18,12 +19,18 @@ func NewGameManager() *GameManager {
 	}
 }
 
-// getGameWithAvailability returns a reference to a game with available spots for
-// players. If one does not exist, nil is returned.
+// getGameWithAvailability returns a reference to an auto-placed game with
+// available spots for players, ignoring named lobbies: those are how a
+// group of friends finds each other, not a pool for randos to be dropped
+// into. If one does not exist, nil is returned.
 func (gm *GameManager) getGameWithAvailability() *Game {
 	var g *Game
 
 	for _, game := range gm.Games {
+		if game.Named {
+			continue
+		}
+
 		spots := game.AvailableColors()
 		if len(spots) > 0 {
 			g = game
@@ -50,6 +57,15 @@ const (
 	gameWidth  = 78
 	gameHeight = 22
 
+	// randomLobby is the SSH username (or lack of one) that falls back to the
+	// default auto-placement behavior instead of naming a lobby.
+	randomLobby = "random"
+
+	// spectatePrefix, when it prefixes the SSH username, attaches the
+	// session to the named lobby as a read-only observer instead of a
+	// player.
+	spectatePrefix = "spectate-"
+
 	keyW = 'w'
 	keyA = 'a'
 	keyS = 's'
@@ -68,20 +84,140 @@ const (
 	keyEscape = 27
 )
 
-func (gm *GameManager) HandleChannel(c io.ReadWriteCloser, wait bool) {
-	g := gm.getGameWithAvailability()
-	if g == nil {
-		g = NewGame(gameWidth, gameHeight)
-		gm.Games[g.Name] = g
+// newGame creates and registers a game, optionally under a fixed name, sized
+// to width x height (falling back to the default size if that's smaller
+// than the minimum playable area), and wires it up to run and log its
+// events.
+func (gm *GameManager) newGame(name string, width, height int) *Game {
+	if width < minPlayableWidth || height < minPlayableHeight {
+		width, height = gameWidth, gameHeight
+	}
+
+	g := NewGame(width, height)
+	if name != "" {
+		g.Name = name
+		g.Named = true
+	}
+	g.Recorder = NewRecorder(g)
+	gm.Games[g.Name] = g
+
+	go g.Run()
+	go logGameEvents(gm, g)
+
+	return g
+}
 
-		go g.Run()
+// removeGame unregisters g, closing its recorder (if any) and its event bus
+// so a long-running server doesn't leak the asciicast/input log file handles
+// or the logGameEvents goroutine of every game that's ever ended. It must
+// only be called once g has no sessions left to emit further events.
+func (gm *GameManager) removeGame(g *Game) {
+	delete(gm.Games, g.Name)
+
+	if g.Recorder != nil {
+		g.Recorder.Close()
 	}
 
-	session := NewSession(c, g.WorldWidth(), g.WorldHeight(),
-		g.AvailableColors()[0])
+	close(g.Events)
+}
+
+// getOrCreateNamedLobby returns the game named name, creating and
+// registering one if it doesn't already exist. This lets a group of friends
+// reconnect to the same match just by agreeing on a username.
+func (gm *GameManager) getOrCreateNamedLobby(name string, width, height int) *Game {
+	if g, ok := gm.Games[name]; ok {
+		return g
+	}
+
+	return gm.newGame(name, width, height)
+}
+
+// HandleNewChannel places a newly connected transport (an SSH channel or a
+// browser WebSocket, anything satisfying io.ReadWriteCloser) into a game and
+// starts servicing its keystrokes, returning the Session it was placed into,
+// or nil if c was turned away (a full named lobby, a "spectate-" lobby that
+// doesn't exist, or a "spectate-" with no name given) after being told why.
+// user is the name the client connected with: "random" (or empty)
+// auto-places into any game with room, anything else joins or creates a
+// lobby by that name, and a "spectate-" prefix watches the named lobby as a
+// read-only observer. width and height are the client's terminal size in
+// characters (0 if unknown) and only affect a lobby's size the first time
+// it's created.
+func (gm *GameManager) HandleNewChannel(c io.ReadWriteCloser, user string, width, height int) *Session {
+	if width > 0 && height > 0 && (width < minPlayableWidth || height < minPlayableHeight) {
+		fmt.Fprintf(c, "Your terminal (%dx%d) is smaller than the minimum playable size "+
+			"(%dx%d); using the default arena size instead.\r\n",
+			width, height, minPlayableWidth, minPlayableHeight)
+		width, height = 0, 0
+	}
+
+	lobby := user
+	isSpectator := strings.HasPrefix(lobby, spectatePrefix)
+	if isSpectator {
+		lobby = strings.TrimPrefix(lobby, spectatePrefix)
+		if lobby == "" {
+			fmt.Fprint(c, "No lobby given to spectate; connect as "+
+				"\"spectate-<name>\".\r\n")
+			c.Close()
+			return nil
+		}
+	}
+
+	var g *Game
+	if lobby == "" || lobby == randomLobby {
+		g = gm.getGameWithAvailability()
+		if g == nil && !isSpectator {
+			g = gm.newGame("", width, height)
+		}
+	} else if isSpectator {
+		// Spectating only ever attaches to a lobby that already exists;
+		// unlike joining, it never creates one.
+		g = gm.Games[lobby]
+	} else {
+		g = gm.getOrCreateNamedLobby(lobby, width, height)
+	}
+
+	if isSpectator {
+		if g == nil {
+			fmt.Fprintf(c, "No such lobby %q to spectate.\r\n", lobby)
+			c.Close()
+			return nil
+		}
+
+		session := NewObserverSession(c, g)
+		g.AddObserver(session)
+
+		go func() {
+			reader := bufio.NewReader(c)
+			for {
+				r, _, err := reader.ReadRune()
+				if err != nil {
+					fmt.Println(err)
+					break
+				}
+
+				if r == keyCtrlC || r == keyEscape {
+					g.RemoveObserver(session)
+					return
+				}
+			}
+		}()
+
+		return session
+	}
+
+	available := g.AvailableColors()
+	if len(available) == 0 {
+		fmt.Fprintf(c, "Lobby %q is full (max %d players); try again later.\r\n",
+			g.Name, len(playerColors))
+		c.Close()
+		return nil
+	}
+
+	session := NewSession(c, g, available[0])
 	g.AddSession(session)
 
-	handleSession := func() {
+	go func() {
 		reader := bufio.NewReader(c)
 		for {
 			r, _, err := reader.ReadRune()
@@ -90,6 +226,13 @@ func (gm *GameManager) HandleChannel(c io.ReadWriteCloser, wait bool) {
 				break
 			}
 
+			switch r {
+			case keyW, keyK, keyComma, keyA, keyH, keyS, keyJ, keyO, keyD, keyL, keyE:
+				if g.Recorder != nil {
+					g.Recorder.RecordInput(g.Tick, session.Player.Color, r)
+				}
+			}
+
 			switch r {
 			case keyW, keyK, keyComma:
 				session.Player.HandleUp()
@@ -101,42 +244,52 @@ func (gm *GameManager) HandleChannel(c io.ReadWriteCloser, wait bool) {
 				session.Player.HandleRight()
 			case keyCtrlC, keyEscape:
 				if g.SessionCount() == 1 {
-					delete(gm.Games, g.Name)
+					g.RemoveSession(session)
+					gm.removeGame(g)
 				}
 
 				g.RemoveSession(session)
 			}
 		}
-	}
+	}()
 
-	if wait {
-		handleSession()
-	} else {
-		go handleSession()
-	}
+	return session
 }
 
 type Session struct {
 	c io.ReadWriteCloser
+	g *Game
 
 	Player *Player
-}
 
-func NewSession(c io.ReadWriteCloser, worldWidth, worldHeight int,
-	color color.Attribute) *Session {
+	// IsObserver marks a spectator session: it has no Player and its
+	// keystrokes are ignored.
+	IsObserver bool
+}
 
-	s := Session{c: c}
-	s.newGame(worldWidth, worldHeight, color)
+func NewSession(c io.ReadWriteCloser, g *Game, color color.Attribute) *Session {
+	s := Session{c: c, g: g}
+	s.newGame(color)
 
 	return &s
 }
 
-func (s *Session) newGame(worldWidth, worldHeight int, color color.Attribute) {
-	s.Player = NewPlayer(s, worldWidth, worldHeight, color)
+// NewObserverSession wraps c as a read-only spectator session with no
+// Player.
+func NewObserverSession(c io.ReadWriteCloser, g *Game) *Session {
+	return &Session{c: c, g: g, IsObserver: true}
+}
+
+func (s *Session) newGame(color color.Attribute) {
+	s.Player = NewPlayer(s, s.g.rng, s.g.WorldWidth(), s.g.WorldHeight(), color)
 }
 
-func (s *Session) StartOver(worldWidth, worldHeight int) {
-	s.newGame(worldWidth, worldHeight, s.Player.Color)
+func (s *Session) StartOver() {
+	s.newGame(s.Player.Color)
+
+	if s.g.Recorder != nil {
+		s.g.Recorder.RecordJoin(s.g.Tick, s.Player)
+	}
 }
 
 func (s *Session) Read(p []byte) (int, error) {
@@ -2,11 +2,13 @@ package main
 
 import (
 	"fmt"
+	"github.com/gorilla/websocket"
 	"golang.org/x/crypto/ssh"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
+	"sync"
 )
 
 const (
@@ -44,11 +46,18 @@ func handler(conn net.Conn, gm *GameManager, config *ssh.ServerConfig) {
 			return
 		}
 
-		// TODO: Remove this -- only temporary while we launch on HN
-		//
-		// To see how many concurrent users are online
-		fmt.Printf("Player joined. Current stats: %d users, %d games\n",
-			gm.SessionCount(), gm.GameCount())
+		// The client's terminal size, discovered from its pty-req if it sends
+		// one before starting the shell, and kept current by later
+		// window-change requests. mu guards width, height and session, all of
+		// which are read and written from both this goroutine and the
+		// request-servicing one below.
+		var (
+			mu            sync.Mutex
+			ready         sync.Once
+			readyCh       = make(chan struct{})
+			width, height int
+			session       *Session
+		)
 
 		// Reject all out of band requests accept for the unix defaults, pty-req and
 		// shell.
@@ -56,17 +65,90 @@ func handler(conn net.Conn, gm *GameManager, config *ssh.ServerConfig) {
 			for req := range in {
 				switch req.Type {
 				case "pty-req":
+					w, h, err := parsePtyRequest(req.Payload)
+					if err == nil {
+						mu.Lock()
+						width, height = w, h
+						mu.Unlock()
+					}
 					req.Reply(true, nil)
+					ready.Do(func() { close(readyCh) })
+					continue
+				case "window-change":
+					w, h, err := parseWindowChange(req.Payload)
+					if err == nil {
+						mu.Lock()
+						s := session
+						mu.Unlock()
+						if s != nil {
+							s.g.Resize(w, h)
+						}
+					}
 					continue
 				case "shell":
 					req.Reply(true, nil)
+					ready.Do(func() { close(readyCh) })
 					continue
 				}
 				req.Reply(false, nil)
 			}
 		}(requests)
 
-		gm.HandleNewChannel(channel, sshConn.User())
+		// Wait for the client to tell us its terminal size (or give up and
+		// start the shell without one) before placing it into a game.
+		<-readyCh
+
+		mu.Lock()
+		w, h := width, height
+		mu.Unlock()
+
+		s := gm.HandleNewChannel(channel, sshConn.User(), w, h)
+
+		mu.Lock()
+		session = s
+		mu.Unlock()
+	}
+}
+
+// upgrader upgrades the HTTP connection serving ./static/ into a WebSocket,
+// letting the xterm.js front end play over the same GameManager path as SSH.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+func serveWS(gm *GameManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			fmt.Println("failed to upgrade websocket connection:", err)
+			return
+		}
+
+		gm.HandleNewChannel(NewWSChannel(conn), "", 0, 0)
+	}
+}
+
+// logGameEvents subscribes to g's event bus and prints a line per
+// notification, replacing the old inline "Player joined" Printf with a
+// proper subscriber.
+func logGameEvents(gm *GameManager, g *Game) {
+	for e := range g.Events {
+		switch e.Type {
+		case EventPlayerJoined:
+			fmt.Printf("Player joined %q. Current stats: %d users, %d games\n",
+				g.Name, gm.SessionCount(), gm.GameCount())
+		case EventPlayerLeft:
+			fmt.Printf("Player left %q. Current stats: %d users, %d games\n",
+				g.Name, gm.SessionCount(), gm.GameCount())
+		case EventPlayerCrashed:
+			fmt.Printf("Player crashed in %q\n", g.Name)
+		case EventPlayerTimedOut:
+			fmt.Printf("Player timed out in %q\n", g.Name)
+		case EventHighScore:
+			fmt.Printf("New high score in %q: %d\n", g.Name, g.HighScore)
+		}
 	}
 }
 
@@ -80,6 +162,20 @@ func port(env, def string) string {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if len(os.Args) < 3 {
+			fmt.Println("usage: sshtron replay <file>")
+			os.Exit(1)
+		}
+
+		if err := Replay(os.Args[2]); err != nil {
+			fmt.Println("replay failed:", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	sshPort := port(sshPortEnv, defaultSshPort)
 	httpPort := port(httpPortEnv, defaultHttpPort)
 
@@ -109,8 +205,12 @@ func main() {
 		httpPort,
 	)
 
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir("./static/")))
+	mux.HandleFunc("/ws", serveWS(gm))
+
 	go func() {
-		panic(http.ListenAndServe(httpPort, http.FileServer(http.Dir("./static/"))))
+		panic(http.ListenAndServe(httpPort, mux))
 	}()
 
 	// Once a ServerConfig has been configured, connections can be
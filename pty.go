@@ -0,0 +1,58 @@
+package main
+
+// Parsing for the SSH pty-req and window-change out-of-band requests (RFC
+// 4254 section 6.2 and 6.7), so the game's arena can match the client's
+// actual terminal size instead of a hard-coded one.
+
+import "golang.org/x/crypto/ssh"
+
+const (
+	// minPlayableWidth and minPlayableHeight are the smallest arena a
+	// terminal can reasonably show; anything smaller falls back to the
+	// default size. minPlayableWidth must stay at or above the widest thing
+	// worldString draws into the top border (the player's score banner,
+	// around 53 characters), or the end of that banner is cut off at the
+	// right wall.
+	minPlayableWidth  = 56
+	minPlayableHeight = 10
+)
+
+// ptyRequestMsg is the payload of a "pty-req" channel request.
+type ptyRequestMsg struct {
+	Term     string
+	Columns  uint32
+	Rows     uint32
+	Width    uint32
+	Height   uint32
+	Modelist string
+}
+
+// windowChangeMsg is the payload of a "window-change" channel request.
+type windowChangeMsg struct {
+	Columns uint32
+	Rows    uint32
+	Width   uint32
+	Height  uint32
+}
+
+// parsePtyRequest extracts the requested terminal size, in characters, from
+// a "pty-req" request payload.
+func parsePtyRequest(payload []byte) (width, height int, err error) {
+	var msg ptyRequestMsg
+	if err := ssh.Unmarshal(payload, &msg); err != nil {
+		return 0, 0, err
+	}
+
+	return int(msg.Columns), int(msg.Rows), nil
+}
+
+// parseWindowChange extracts the new terminal size, in characters, from a
+// "window-change" request payload.
+func parseWindowChange(payload []byte) (width, height int, err error) {
+	var msg windowChangeMsg
+	if err := ssh.Unmarshal(payload, &msg); err != nil {
+		return 0, 0, err
+	}
+
+	return int(msg.Columns), int(msg.Rows), nil
+}
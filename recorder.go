@@ -0,0 +1,290 @@
+package main
+
+// Recorder captures everything needed to play a game back later: an
+// asciicast v2 transcript of what was rendered, and a compact log of when
+// each player joined and what they typed. Enabled per-game by setting
+// recordDirEnv; disabled by default.
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// recordDirEnv, when set, turns on recording: every game started gets an
+// asciicast transcript and an input log written under this directory.
+const recordDirEnv = "RECORD_DIR"
+
+// Recorder ties an asciicast transcript and an input log to a single Game.
+type Recorder struct {
+	cast  *asciicastWriter
+	input *inputLogWriter
+
+	// primary is the first session to render a frame. An asciicast is a
+	// single terminal stream, so it's the only perspective recorded; later
+	// sessions' frames are skipped.
+	primary *Session
+}
+
+// NewRecorder opens a Recorder for g if recordDirEnv is set, or returns nil
+// if recording is disabled or the files couldn't be opened.
+func NewRecorder(g *Game) *Recorder {
+	dir := os.Getenv(recordDirEnv)
+	if dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Println("recorder: could not create recording directory:", err)
+		return nil
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%s-%d", g.Name, g.Seed))
+
+	cast, err := newAsciicastWriter(base+".cast", g.WorldWidth()+2, g.WorldHeight()+2)
+	if err != nil {
+		fmt.Println("recorder: could not open asciicast file:", err)
+		return nil
+	}
+
+	input, err := newInputLogWriter(base+".input", g.Seed)
+	if err != nil {
+		fmt.Println("recorder: could not open input log:", err)
+		cast.Close()
+		return nil
+	}
+
+	return &Recorder{cast: cast, input: input}
+}
+
+// RecordOutput feeds s's just-rendered frame into the asciicast transcript.
+func (r *Recorder) RecordOutput(s *Session, frame string) {
+	if r.primary == nil {
+		r.primary = s
+	}
+	if s != r.primary {
+		return
+	}
+
+	if err := r.cast.WriteOutput(frame); err != nil {
+		fmt.Println("recorder: failed to write asciicast frame:", err)
+	}
+}
+
+// RecordJoin logs p's starting position at tick, so replay can recreate it
+// without needing its own source of randomness.
+func (r *Recorder) RecordJoin(tick uint32, p *Player) {
+	if err := r.input.WriteJoin(tick, p.Color, *p.Pos); err != nil {
+		fmt.Println("recorder: failed to write join record:", err)
+	}
+}
+
+// RecordInput logs a single keystroke handled on behalf of the player with
+// the given color at tick.
+func (r *Recorder) RecordInput(tick uint32, playerColor color.Attribute, key rune) {
+	if err := r.input.WriteInput(tick, playerColor, key); err != nil {
+		fmt.Println("recorder: failed to write input record:", err)
+	}
+}
+
+func (r *Recorder) Close() {
+	r.cast.Close()
+	r.input.Close()
+}
+
+// asciicastWriter writes an asciicast v2 (https://github.com/asciinema/asciinema/blob/develop/doc/asciicast-v2.md)
+// transcript: a header line followed by one "output" event line per frame,
+// each timestamped in seconds since the header.
+type asciicastWriter struct {
+	w     *bufio.Writer
+	f     *os.File
+	start time.Time
+}
+
+func newAsciicastWriter(path string, width, height int) (*asciicastWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+	header, err := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     width,
+		"height":    height,
+		"timestamp": time.Now().Unix(),
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := w.WriteByte('\n'); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &asciicastWriter{w: w, f: f, start: time.Now()}, nil
+}
+
+func (a *asciicastWriter) WriteOutput(frame string) error {
+	entry, err := json.Marshal([]interface{}{
+		time.Since(a.start).Seconds(),
+		"o",
+		frame,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.w.Write(entry); err != nil {
+		return err
+	}
+	if err := a.w.WriteByte('\n'); err != nil {
+		return err
+	}
+
+	return a.w.Flush()
+}
+
+func (a *asciicastWriter) Close() error {
+	a.w.Flush()
+	return a.f.Close()
+}
+
+// Input log record types.
+const (
+	inputRecordJoin byte = iota
+	inputRecordInput
+)
+
+// inputLogWriter writes a compact binary log: an int64 seed, followed by a
+// stream of records each prefixed with its type, a tick, and a player color.
+type inputLogWriter struct {
+	w *bufio.Writer
+	f *os.File
+}
+
+func newInputLogWriter(path string, seed int64) (*inputLogWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+	if err := binary.Write(w, binary.BigEndian, seed); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &inputLogWriter{w: w, f: f}, nil
+}
+
+func (l *inputLogWriter) WriteJoin(tick uint32, playerColor color.Attribute, pos Position) error {
+	for _, v := range []interface{}{inputRecordJoin, tick, int32(playerColor), pos.X, pos.Y} {
+		if err := binary.Write(l.w, binary.BigEndian, v); err != nil {
+			return err
+		}
+	}
+
+	return l.w.Flush()
+}
+
+func (l *inputLogWriter) WriteInput(tick uint32, playerColor color.Attribute, key rune) error {
+	for _, v := range []interface{}{inputRecordInput, tick, int32(playerColor), int32(key)} {
+		if err := binary.Write(l.w, binary.BigEndian, v); err != nil {
+			return err
+		}
+	}
+
+	return l.w.Flush()
+}
+
+func (l *inputLogWriter) Close() error {
+	l.w.Flush()
+	return l.f.Close()
+}
+
+// inputLogRecord is a single decoded entry from an input log: a join (Pos
+// set) or a keystroke (Key set).
+type inputLogRecord struct {
+	Type  byte
+	Tick  uint32
+	Color color.Attribute
+	Pos   Position
+	Key   rune
+}
+
+// inputLogReader reads back what an inputLogWriter wrote.
+type inputLogReader struct {
+	r    *bufio.Reader
+	f    *os.File
+	Seed int64
+}
+
+func openInputLogReader(path string) (*inputLogReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(f)
+	var seed int64
+	if err := binary.Read(r, binary.BigEndian, &seed); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &inputLogReader{r: r, f: f, Seed: seed}, nil
+}
+
+// Next returns the next record, or io.EOF once the log is exhausted.
+func (l *inputLogReader) Next() (*inputLogRecord, error) {
+	var typ byte
+	if err := binary.Read(l.r, binary.BigEndian, &typ); err != nil {
+		return nil, err
+	}
+
+	rec := &inputLogRecord{Type: typ}
+	if err := binary.Read(l.r, binary.BigEndian, &rec.Tick); err != nil {
+		return nil, err
+	}
+
+	var c int32
+	if err := binary.Read(l.r, binary.BigEndian, &c); err != nil {
+		return nil, err
+	}
+	rec.Color = color.Attribute(c)
+
+	switch typ {
+	case inputRecordJoin:
+		if err := binary.Read(l.r, binary.BigEndian, &rec.Pos.X); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(l.r, binary.BigEndian, &rec.Pos.Y); err != nil {
+			return nil, err
+		}
+	case inputRecordInput:
+		var k int32
+		if err := binary.Read(l.r, binary.BigEndian, &k); err != nil {
+			return nil, err
+		}
+		rec.Key = rune(k)
+	}
+
+	return rec, nil
+}
+
+func (l *inputLogReader) Close() error {
+	return l.f.Close()
+}
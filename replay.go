@@ -0,0 +1,129 @@
+package main
+
+// Replay reconstructs a game recorded by a Recorder's input log and plays it
+// back, deterministically, to the local terminal via a TermChannel.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// discardChannel is a no-op io.ReadWriteCloser used for the ghost sessions
+// replay creates to stand in for the original players; nothing ever reads
+// from or needs to be sent to them.
+type discardChannel struct{}
+
+func (discardChannel) Read(p []byte) (int, error)  { return 0, io.EOF }
+func (discardChannel) Write(p []byte) (int, error) { return len(p), nil }
+func (discardChannel) Close() error                { return nil }
+
+// replayTickDelta is the millisecond step g.Run's live 60Hz loop advances
+// Update by on every tick. Stepping the simulation with this fixed delta,
+// once per recorded tick, instead of running the real-time g.Run loop and
+// sleeping to approximate it, is what makes replay reproduce the exact same
+// trajectories and collisions every time rather than drifting with
+// scheduling jitter.
+const replayTickDelta = float64(time.Second) / 60 / float64(time.Millisecond)
+
+// Replay reads the input log at path and reproduces the game it recorded,
+// deterministically, rendering it to the local terminal.
+func Replay(path string) error {
+	log, err := openInputLogReader(path)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	g := NewGameWithSeed(gameWidth, gameHeight, log.Seed)
+
+	tc := NewTermChannel()
+	defer tc.Restore()
+
+	viewer := NewObserverSession(tc, g)
+	g.AddObserver(viewer)
+
+	// Watch for the viewer asking to quit. Nothing else ever reads from tc,
+	// so without this Ctrl-C would just sit in the tty's input buffer
+	// forever and the deferred Restore above would never get a chance to
+	// run.
+	quit := make(chan struct{})
+	go func() {
+		reader := bufio.NewReader(tc)
+		for {
+			r, _, err := reader.ReadRune()
+			if err != nil {
+				return
+			}
+			if r == keyCtrlC || r == keyEscape {
+				close(quit)
+				return
+			}
+		}
+	}()
+
+	// sessions tracks the one Session per color a join record introduces. A
+	// later join for the same color is a recorded respawn (Session.StartOver
+	// on the original), not a new player, so it reuses the Session and just
+	// swaps in the recorded position rather than going through g.AddSession
+	// again.
+	sessions := map[color.Attribute]*Session{}
+
+	rec, recErr := log.Next()
+
+	for tick := uint32(0); recErr == nil; tick++ {
+		// Apply every record tagged with the tick we're about to simulate
+		// before simulating it, exactly as they were applied live.
+		for recErr == nil && rec.Tick == tick {
+			switch rec.Type {
+			case inputRecordJoin:
+				if s, ok := sessions[rec.Color]; ok {
+					s.Player = NewPlayerAt(s, rec.Pos, rec.Color)
+				} else {
+					s := &Session{c: discardChannel{}, g: g}
+					s.Player = NewPlayerAt(s, rec.Pos, rec.Color)
+					sessions[rec.Color] = s
+					g.AddSession(s)
+				}
+			case inputRecordInput:
+				if s := sessions[rec.Color]; s != nil {
+					switch rec.Key {
+					case keyW, keyK, keyComma:
+						s.Player.HandleUp()
+					case keyA, keyH:
+						s.Player.HandleLeft()
+					case keyS, keyJ, keyO:
+						s.Player.HandleDown()
+					case keyD, keyL, keyE:
+						s.Player.HandleRight()
+					}
+				}
+			}
+
+			rec, recErr = log.Next()
+		}
+
+		g.Update(replayTickDelta)
+
+		// Render at the same 10Hz g.Run's redraw loop would.
+		if tick%6 == 0 {
+			g.Render(viewer)
+		}
+
+		select {
+		case <-quit:
+			return nil
+		case <-time.After(time.Second / 60):
+		}
+	}
+	if recErr != io.EOF {
+		return recErr
+	}
+
+	fmt.Fprint(viewer, "\r\n\r\nReplay finished. Press Ctrl-C to exit.\r\n")
+	<-quit
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+// WSChannel adapts a browser WebSocket connection to an io.ReadWriteCloser so
+// it can be driven through the same Session/Game code path as an SSH
+// channel.
+
+import (
+	"io"
+
+	"github.com/gorilla/websocket"
+)
+
+type WSChannel struct {
+	conn *websocket.Conn
+	r    io.Reader
+}
+
+func NewWSChannel(conn *websocket.Conn) *WSChannel {
+	return &WSChannel{conn: conn}
+}
+
+// Read pulls bytes out of the current WebSocket message, fetching a new
+// message once the last one has been drained.
+func (wc *WSChannel) Read(p []byte) (int, error) {
+	for {
+		if wc.r == nil {
+			_, r, err := wc.conn.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			wc.r = r
+		}
+
+		n, err := wc.r.Read(p)
+		if err == io.EOF {
+			wc.r = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (wc *WSChannel) Write(p []byte) (int, error) {
+	if err := wc.conn.WriteMessage(websocket.TextMessage, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (wc *WSChannel) Close() error {
+	return wc.conn.Close()
+}